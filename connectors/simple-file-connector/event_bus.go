@@ -0,0 +1,81 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/ThisaraWeerakoon/Synpase-Go-Connector-PoC/protocol/shimpb"
+)
+
+// eventBus fans lifecycle/log events out to every Events stream currently
+// subscribed via shimServer.Events. Past the initial startup window there's
+// no backlog: a subscriber that connects after an event was published simply
+// never sees it, same as tailing a live log stream. The one exception is
+// events published before anyone has ever subscribed (namely "started" and
+// "ready", which are published while main is still setting up the gRPC
+// server, long before a manager can have dialed us and called Events) - those
+// are held in backlog and replayed to the first subscriber, since otherwise
+// no subscriber could ever have observed them.
+type eventBus struct {
+	mu             sync.Mutex
+	subs           map[int]chan *shimpb.Event
+	next           int
+	backlog        []*shimpb.Event
+	everSubscribed bool
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{subs: make(map[int]chan *shimpb.Event)}
+}
+
+// subscribe registers a new listener and returns its id (for unsubscribe)
+// and the channel events will be delivered on. The very first subscriber
+// additionally drains and receives any backlog accumulated before it
+// arrived.
+func (b *eventBus) subscribe() (int, <-chan *shimpb.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.next
+	b.next++
+	ch := make(chan *shimpb.Event, 32)
+	b.subs[id] = ch
+
+	if !b.everSubscribed {
+		b.everSubscribed = true
+		for _, ev := range b.backlog {
+			select {
+			case ch <- ev:
+			default:
+			}
+		}
+		b.backlog = nil
+	}
+	return id, ch
+}
+
+func (b *eventBus) unsubscribe(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ch, ok := b.subs[id]; ok {
+		delete(b.subs, id)
+		close(ch)
+	}
+}
+
+// publish delivers ev to every current subscriber, dropping it for any
+// subscriber whose buffer is full rather than blocking the caller. If no one
+// has ever subscribed yet, ev is held in backlog instead (see eventBus) so
+// it isn't lost before the first subscriber can possibly have connected.
+func (b *eventBus) publish(ev *shimpb.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.everSubscribed {
+		b.backlog = append(b.backlog, ev)
+		return
+	}
+	for _, ch := range b.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
@@ -1,89 +1,397 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
 
-	"github.com/ThisaraWeerakoon/Synpase-Go-Connector-PoC/protocol"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ThisaraWeerakoon/Synpase-Go-Connector-PoC/protocol/shimpb"
 )
 
+// streamChunkSize is the frame size StreamInvoke reads/writes file content
+// in. Matched by the manager's own streamChunkSize in connector_manager.go.
+const streamChunkSize = 32 * 1024
+
 var port *int
 
+// listenFDsEnvVar mirrors systemd's socket-activation protocol: when set,
+// the manager has already created and bound the listening socket and passed
+// it to us via cmd.ExtraFiles, starting at fd 3 (0-2 are stdin/stdout/stderr).
+const listenFDsEnvVar = "SYNAPSE_LISTEN_FDS"
+
+const firstExtraFD = 3
+
+// defaultShutdownGracePeriod bounds how long Shutdown waits for in-flight
+// RPCs to finish on its own before forcing the gRPC server to stop, when the
+// caller doesn't specify GracePeriodMs.
+const defaultShutdownGracePeriod = 5 * time.Second
+
+// shimServer implements shimpb.ShimServer for the file connector.
+type shimServer struct {
+	shimpb.UnimplementedShimServer
+	events     *eventBus
+	grpcServer *grpc.Server
+}
+
 func main() {
-	port = flag.Int("port", 0, "Port to listen on")
+	port = flag.Int("port", 0, "Port to listen on (ignored when a Unix socket path is given)")
+	socketPath := flag.String("socket", "", "Unix domain socket path to listen on; takes precedence over -port")
 	flag.Parse()
 
-	if *port == 0 {
-		log.Fatal("Connector Error: -port flag is required.")
+	listener, err := listenerFromEnv()
+	if err != nil {
+		log.Fatalf("Connector Error: Failed to use preopened listener from %s: %v", listenFDsEnvVar, err)
 	}
+	if listener == nil {
+		switch {
+		case *socketPath != "":
+			_ = os.Remove(*socketPath)
+			listener, err = net.Listen("unix", *socketPath)
+			if err != nil {
+				log.Fatalf("Connector Error: Failed to listen on unix socket %s: %v", *socketPath, err)
+			}
+			log.Printf("SimpleFileConnector: Listening on unix socket %s", *socketPath)
+		case *port != 0:
+			listener, err = net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", *port))
+			if err != nil {
+				log.Fatalf("Connector Error: Failed to listen on port %d: %v", *port, err)
+			}
+			log.Printf("SimpleFileConnector: Listening on port %d", *port)
+		default:
+			log.Fatal("Connector Error: one of -socket, -port, or " + listenFDsEnvVar + " must be given.")
+		}
+	}
+	defer listener.Close()
+
+	events := newEventBus()
+	events.publish(&shimpb.Event{Kind: "started", Fields: map[string]string{"connector": "SimpleFileConnector"}})
 
-	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", *port))
+	grpcServer := grpc.NewServer()
+	server := &shimServer{events: events, grpcServer: grpcServer}
+	shimpb.RegisterShimServer(grpcServer, server)
+
+	events.publish(&shimpb.Event{Kind: "ready", Fields: map[string]string{"connector": "SimpleFileConnector"}})
+
+	// On SIGTERM/SIGINT, stop accepting new connections but let in-flight
+	// RPCs (e.g. a StreamInvoke mid-upload) finish before main returns,
+	// rather than cutting them off mid-operation. This is the same drain
+	// the Shutdown RPC below triggers; the manager uses the RPC when it can
+	// reach us and falls back to SIGTERM otherwise.
+	go func() {
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+		sig := <-sigCh
+		log.Printf("SimpleFileConnector: Received %s, draining in-flight operations...", sig)
+		server.drain(defaultShutdownGracePeriod)
+	}()
+
+	if err := grpcServer.Serve(listener); err != nil {
+		log.Fatalf("Connector Error: gRPC server stopped serving: %v", err)
+	}
+}
+
+// listenerFromEnv returns a net.Listener built from a file descriptor the
+// manager preopened and handed down via cmd.ExtraFiles, or (nil, nil) if
+// listenFDsEnvVar isn't set so the caller falls back to -socket/-port.
+func listenerFromEnv() (net.Listener, error) {
+	raw := os.Getenv(listenFDsEnvVar)
+	if raw == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("invalid %s value %q", listenFDsEnvVar, raw)
+	}
+	// The manager only ever hands us one socket today; accept the first fd.
+	f := os.NewFile(uintptr(firstExtraFD), "synapse-listener")
+	listener, err := net.FileListener(f)
 	if err != nil {
-		log.Fatalf("Connector Error: Failed to listen on port %d: %v", *port, err)
+		return nil, err
 	}
-	defer listener.Close()
-	log.Printf("SimpleFileConnector: Listening on port %d", *port)
+	// FileListener dup()s the fd, so close our copy of the original.
+	_ = f.Close()
+	log.Printf("SimpleFileConnector: Listening on preopened socket (fd %d) via %s", firstExtraFD, listenFDsEnvVar)
+	return listener, nil
+}
 
-	for {
-		conn, err := listener.Accept()
-		if err != nil {
-			log.Printf("Connector Error: Failed to accept connection: %v", err)
-			continue
-		}
-		go handleConnection(conn)
+func (s *shimServer) Create(ctx context.Context, req *shimpb.CreateRequest) (*shimpb.CreateResponse, error) {
+	return &shimpb.CreateResponse{Success: true}, nil
+}
+
+func (s *shimServer) HealthCheck(ctx context.Context, req *shimpb.HealthCheckRequest) (*shimpb.HealthCheckResponse, error) {
+	return &shimpb.HealthCheckResponse{Status: shimpb.HealthCheckResponse_SERVING}, nil
+}
+
+// drain publishes a "draining" event and stops grpcServer from accepting new
+// RPCs, letting in-flight ones finish. If they haven't finished within grace,
+// it force-stops rather than waiting on a connector that never drains - the
+// manager still escalates to SIGKILL on its own timeline regardless, but a
+// connector that can enforce its own grace period doesn't depend on that.
+func (s *shimServer) drain(grace time.Duration) {
+	s.events.publish(&shimpb.Event{Kind: "draining", Fields: map[string]string{"connector": "SimpleFileConnector"}})
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(grace):
+		log.Printf("SimpleFileConnector: Grace period elapsed before drain finished; forcing stop")
+		s.grpcServer.Stop()
 	}
 }
 
-func handleConnection(conn net.Conn) {
-	defer conn.Close()
-	log.Println("SimpleFileConnector: New connection accepted")
+// Shutdown lets the manager ask us to drain over the control connection
+// instead of only via SIGTERM - useful when the manager wants confirmation
+// the request was received. It returns as soon as draining has started;
+// the caller doesn't block on it finishing here, since the drain itself can
+// take as long as grace allows.
+func (s *shimServer) Shutdown(ctx context.Context, req *shimpb.ShutdownRequest) (*shimpb.ShutdownResponse, error) {
+	grace := time.Duration(req.GracePeriodMs) * time.Millisecond
+	if grace <= 0 {
+		grace = defaultShutdownGracePeriod
+	}
+	log.Printf("SimpleFileConnector: Shutdown requested (grace period %s), draining in-flight operations...", grace)
+	go s.drain(grace)
+	return &shimpb.ShutdownResponse{Accepted: true}, nil
+}
 
-	decoder := json.NewDecoder(conn)
-	encoder := json.NewEncoder(conn)
+// Events streams this connector's lifecycle/log records to whoever is
+// tailing them (the manager's ConnectorManager.Subscribe, today).
+func (s *shimServer) Events(req *shimpb.HealthCheckRequest, stream shimpb.Shim_EventsServer) error {
+	id, ch := s.events.subscribe()
+	defer s.events.unsubscribe(id)
 
-	var req protocol.ConnectorOperationRequest
-	if err := decoder.Decode(&req); err != nil {
-		log.Printf("SimpleFileConnector: Error decoding request: %v", err)
-		_ = encoder.Encode(protocol.ConnectorOperationResponse{
-			Success:      false,
-			ErrorMessage: fmt.Sprintf("Failed to decode request: %v", err),
-		})
-		return
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(ev); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
 	}
+}
 
+func (s *shimServer) Invoke(ctx context.Context, req *shimpb.ConnectorOperationRequest) (*shimpb.ConnectorOperationResponse, error) {
 	log.Printf("SimpleFileConnector: Received operation '%s' for connector '%s'", req.OperationName, req.ConnectorName)
 
-	var resp protocol.ConnectorOperationResponse
+	var messageID string
+	if req.MessageContextIn != nil {
+		messageID = req.MessageContextIn.MessageId
+	}
+	s.events.publish(&shimpb.Event{
+		MessageId: messageID,
+		Level:     shimpb.Event_INFO,
+		Kind:      "operation_begin",
+		Fields:    map[string]string{"operation": req.OperationName, "connector": req.ConnectorName},
+	})
+
+	var resp *shimpb.ConnectorOperationResponse
 	switch req.OperationName {
 	case "create":
 		resp = createFile(req)
 	case "read":
 		resp = readFile(req)
 	default:
-		resp = protocol.ConnectorOperationResponse{
+		resp = &shimpb.ConnectorOperationResponse{
 			Success:           false,
 			MessageContextOut: req.MessageContextIn,
 			ErrorMessage:      fmt.Sprintf("Unknown operation: %s", req.OperationName),
 		}
 	}
 
-	if err := encoder.Encode(resp); err != nil {
-		log.Printf("SimpleFileConnector: Error encoding response: %v", err)
+	endEvent := &shimpb.Event{
+		MessageId: messageID,
+		Level:     shimpb.Event_INFO,
+		Kind:      "operation_end",
+		Fields:    map[string]string{"operation": req.OperationName, "connector": req.ConnectorName, "success": strconv.FormatBool(resp.Success)},
+	}
+	if !resp.Success {
+		endEvent.Level = shimpb.Event_ERROR
+		endEvent.Kind = "error"
+		endEvent.Fields["error"] = resp.ErrorMessage
+	}
+	s.events.publish(endEvent)
+
+	return resp, nil
+}
+
+// StreamInvoke is Invoke's counterpart for payloads too large to buffer in
+// memory: "create" reads its content from PayloadChunk frames instead of
+// OperationParams["content"], and "read" streams the file back chunk by
+// chunk instead of returning it all in MessageContextOut.Payload.
+func (s *shimServer) StreamInvoke(stream shimpb.Shim_StreamInvokeServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if first.Request == nil {
+		return status.Errorf(codes.InvalidArgument, "first StreamInvoke frame must carry a request")
+	}
+	req := first.Request
+	log.Printf("SimpleFileConnector: Received streamed operation '%s' for connector '%s'", req.OperationName, req.ConnectorName)
+
+	switch req.OperationName {
+	case "create":
+		return s.streamCreateFile(stream, req, first)
+	case "read":
+		return s.streamReadFile(stream, req)
+	default:
+		return stream.Send(&shimpb.StreamChunk{
+			Response:  errResponse(req, fmt.Sprintf("Unknown operation: %s", req.OperationName)),
+			LastChunk: true,
+		})
 	}
 }
 
-func createFile(req protocol.ConnectorOperationRequest) protocol.ConnectorOperationResponse {
+func errResponse(req *shimpb.ConnectorOperationRequest, msg string) *shimpb.ConnectorOperationResponse {
+	return &shimpb.ConnectorOperationResponse{Success: false, MessageContextOut: req.MessageContextIn, ErrorMessage: msg}
+}
+
+// streamCreateFile writes the PayloadChunk frames following req to disk via
+// io.Copy-style incremental writes, rather than buffering the whole upload
+// in memory like createFile does for small inline payloads.
+func (s *shimServer) streamCreateFile(stream shimpb.Shim_StreamInvokeServer, req *shimpb.ConnectorOperationRequest, first *shimpb.StreamChunk) error {
+	filename, ok := req.OperationParams["filename"].(string)
+	if !ok {
+		return stream.Send(&shimpb.StreamChunk{Response: errResponse(req, "filename parameter missing/invalid"), LastChunk: true})
+	}
+
+	baseDir, _ := req.ConnectorConfig["baseDirectory"].(string)
+	if baseDir == "" {
+		baseDir = "."
+	}
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return stream.Send(&shimpb.StreamChunk{Response: errResponse(req, fmt.Sprintf("failed to create base directory %s: %v", baseDir, err)), LastChunk: true})
+	}
+	fullPath := filepath.Join(baseDir, filename)
+
+	f, err := os.Create(fullPath)
+	if err != nil {
+		return stream.Send(&shimpb.StreamChunk{Response: errResponse(req, fmt.Sprintf("failed to create file %s: %v", fullPath, err)), LastChunk: true})
+	}
+	defer f.Close()
+
+	writeChunk := func(chunk *shimpb.StreamChunk) error {
+		if len(chunk.PayloadChunk) == 0 {
+			return nil
+		}
+		_, err := f.Write(chunk.PayloadChunk)
+		return err
+	}
+
+	if err := writeChunk(first); err != nil {
+		return stream.Send(&shimpb.StreamChunk{Response: errResponse(req, fmt.Sprintf("failed to write file %s: %v", fullPath, err)), LastChunk: true})
+	}
+	for done := first.LastChunk; !done; {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := writeChunk(chunk); err != nil {
+			return stream.Send(&shimpb.StreamChunk{Response: errResponse(req, fmt.Sprintf("failed to write file %s: %v", fullPath, err)), LastChunk: true})
+		}
+		done = chunk.LastChunk
+	}
+
+	outCtx := copyMessageContext(req.MessageContextIn)
+	outCtx.Properties["file.write.path"] = fullPath
+	outCtx.Properties["file.write.status"] = "success"
+	return stream.Send(&shimpb.StreamChunk{
+		Response:  &shimpb.ConnectorOperationResponse{Success: true, MessageContextOut: outCtx},
+		LastChunk: true,
+	})
+}
+
+// streamReadFile streams the file back in streamChunkSize frames via
+// io.Copy-style incremental reads, rather than buffering the whole file in
+// memory like readFile does for small inline payloads.
+func (s *shimServer) streamReadFile(stream shimpb.Shim_StreamInvokeServer, req *shimpb.ConnectorOperationRequest) error {
+	filename, ok := req.OperationParams["filename"].(string)
+	if !ok {
+		return stream.Send(&shimpb.StreamChunk{Response: errResponse(req, "filename parameter missing/invalid"), LastChunk: true})
+	}
+	baseDir, _ := req.ConnectorConfig["baseDirectory"].(string)
+	if baseDir == "" {
+		baseDir = "."
+	}
+	fullPath := filepath.Join(baseDir, filename)
+
+	f, err := os.Open(fullPath)
+	if err != nil {
+		return stream.Send(&shimpb.StreamChunk{Response: errResponse(req, fmt.Sprintf("failed to read file %s: %v", fullPath, err)), LastChunk: true})
+	}
+	defer f.Close()
+
+	outCtx := copyMessageContext(req.MessageContextIn)
+	outCtx.Properties["file.read.path"] = fullPath
+	if err := stream.Send(&shimpb.StreamChunk{Response: &shimpb.ConnectorOperationResponse{Success: true, MessageContextOut: outCtx}}); err != nil {
+		return err
+	}
+
+	buf := make([]byte, streamChunkSize)
+	for {
+		n, rerr := f.Read(buf)
+		if n > 0 {
+			chunk := make([]byte, n)
+			copy(chunk, buf[:n])
+			if serr := stream.Send(&shimpb.StreamChunk{PayloadChunk: chunk}); serr != nil {
+				return serr
+			}
+		}
+		if rerr == io.EOF {
+			return stream.Send(&shimpb.StreamChunk{LastChunk: true})
+		}
+		if rerr != nil {
+			return rerr
+		}
+	}
+}
+
+// copyMessageContext returns a shallow copy of in (or a fresh, empty context
+// if in is nil) so handlers can fill in Properties/Payload without mutating
+// the request the caller still holds a reference to.
+func copyMessageContext(in *shimpb.MessageContext) *shimpb.MessageContext {
+	out := &shimpb.MessageContext{}
+	if in != nil {
+		*out = *in
+	}
+	if out.Properties == nil {
+		out.Properties = make(map[string]interface{})
+	}
+	return out
+}
+
+func createFile(req *shimpb.ConnectorOperationRequest) *shimpb.ConnectorOperationResponse {
 	filename, okFile := req.OperationParams["filename"].(string)
 	content, okContent := req.OperationParams["content"].(string)
 
 	if !okFile || !okContent {
-		return protocol.ConnectorOperationResponse{Success: false, MessageContextOut: req.MessageContextIn, ErrorMessage: "filename or content parameter missing/invalid"}
+		return &shimpb.ConnectorOperationResponse{Success: false, MessageContextOut: req.MessageContextIn, ErrorMessage: "filename or content parameter missing/invalid"}
 	}
 
 	baseDir, _ := req.ConnectorConfig["baseDirectory"].(string)
@@ -91,29 +399,52 @@ func createFile(req protocol.ConnectorOperationRequest) protocol.ConnectorOperat
 		baseDir = "." // Default to current directory if not configured
 	}
 	if err := os.MkdirAll(baseDir, 0755); err != nil {
-		return protocol.ConnectorOperationResponse{Success: false, MessageContextOut: req.MessageContextIn, ErrorMessage: fmt.Sprintf("failed to create base directory %s: %v", baseDir, err)}
+		return &shimpb.ConnectorOperationResponse{Success: false, MessageContextOut: req.MessageContextIn, ErrorMessage: fmt.Sprintf("failed to create base directory %s: %v", baseDir, err)}
 	}
 	fullPath := filepath.Join(baseDir, filename)
 
 	err := ioutil.WriteFile(fullPath, []byte(content), 0644)
 	if err != nil {
-		return protocol.ConnectorOperationResponse{Success: false, MessageContextOut: req.MessageContextIn, ErrorMessage: fmt.Sprintf("failed to write file %s: %v", fullPath, err)}
+		return &shimpb.ConnectorOperationResponse{Success: false, MessageContextOut: req.MessageContextIn, ErrorMessage: fmt.Sprintf("failed to write file %s: %v", fullPath, err)}
 	}
 
-	outCtx := req.MessageContextIn
-	if outCtx.Properties == nil {
-		outCtx.Properties = make(map[string]interface{})
-	}
+	outCtx := copyMessageContext(req.MessageContextIn)
 	outCtx.Properties["file.write.path"] = fullPath
 	outCtx.Properties["file.write.status"] = "success"
 
-	return protocol.ConnectorOperationResponse{Success: true, MessageContextOut: outCtx}
+	return &shimpb.ConnectorOperationResponse{Success: true, MessageContextOut: outCtx}
 }
 
-func readFile(req protocol.ConnectorOperationRequest) protocol.ConnectorOperationResponse {
+// defaultMaxInlinePayloadBytes is the fallback read-size guard used when
+// the request's ConnectorConfig doesn't carry connectorConfigMaxInlineKey
+// (e.g. an older manager). Matches the manager's own default in
+// connector_manager.go so behavior is consistent either way.
+const defaultMaxInlinePayloadBytes = 4 << 20 // 4 MiB
+
+// connectorConfigMaxInlineKey mirrors the manager's constant of the same
+// name in synapse-server/connector_manager.go.
+const connectorConfigMaxInlineKey = "maxInlinePayloadBytes"
+
+// maxInlinePayloadBytes extracts the inline size guard from cfg. Numbers
+// decode as float64 once they've crossed the JSON codec, so handle that
+// alongside the plain int a same-process caller (e.g. a test) might pass.
+func maxInlinePayloadBytes(cfg map[string]interface{}) int64 {
+	switch v := cfg[connectorConfigMaxInlineKey].(type) {
+	case float64:
+		return int64(v)
+	case int:
+		return int64(v)
+	case int64:
+		return v
+	default:
+		return defaultMaxInlinePayloadBytes
+	}
+}
+
+func readFile(req *shimpb.ConnectorOperationRequest) *shimpb.ConnectorOperationResponse {
 	filename, okFile := req.OperationParams["filename"].(string)
 	if !okFile {
-		return protocol.ConnectorOperationResponse{Success: false, MessageContextOut: req.MessageContextIn, ErrorMessage: "filename parameter missing/invalid"}
+		return &shimpb.ConnectorOperationResponse{Success: false, MessageContextOut: req.MessageContextIn, ErrorMessage: "filename parameter missing/invalid"}
 	}
 	baseDir, _ := req.ConnectorConfig["baseDirectory"].(string)
 	if baseDir == "" {
@@ -121,15 +452,24 @@ func readFile(req protocol.ConnectorOperationRequest) protocol.ConnectorOperatio
 	}
 	fullPath := filepath.Join(baseDir, filename)
 
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return &shimpb.ConnectorOperationResponse{Success: false, MessageContextOut: req.MessageContextIn, ErrorMessage: fmt.Sprintf("failed to read file %s: %v", fullPath, err)}
+	}
+	if maxBytes := maxInlinePayloadBytes(req.ConnectorConfig); info.Size() > maxBytes {
+		return &shimpb.ConnectorOperationResponse{
+			Success:           false,
+			MessageContextOut: req.MessageContextIn,
+			ErrorMessage:      fmt.Sprintf("file %s is %d bytes, exceeding the %d byte inline read limit; use StreamInvoke instead", fullPath, info.Size(), maxBytes),
+		}
+	}
+
 	data, err := ioutil.ReadFile(fullPath)
 	if err != nil {
-		return protocol.ConnectorOperationResponse{Success: false, MessageContextOut: req.MessageContextIn, ErrorMessage: fmt.Sprintf("failed to read file %s: %v", fullPath, err)}
+		return &shimpb.ConnectorOperationResponse{Success: false, MessageContextOut: req.MessageContextIn, ErrorMessage: fmt.Sprintf("failed to read file %s: %v", fullPath, err)}
 	}
-	outCtx := req.MessageContextIn
+	outCtx := copyMessageContext(req.MessageContextIn)
 	outCtx.Payload = data
-	if outCtx.Properties == nil {
-		outCtx.Properties = make(map[string]interface{})
-	}
 	outCtx.Properties["file.read.path"] = fullPath
-	return protocol.ConnectorOperationResponse{Success: true, MessageContextOut: outCtx}
-}
\ No newline at end of file
+	return &shimpb.ConnectorOperationResponse{Success: true, MessageContextOut: outCtx}
+}
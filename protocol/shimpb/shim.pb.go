@@ -0,0 +1,105 @@
+// Package shimpb holds hand-written Go types mirroring protocol/shim.proto.
+// They are NOT protoc output: this module deliberately skips the protobuf
+// binary wire format (and its code-generation toolchain) and instead
+// carries these structs as JSON over gRPC's framing via the jsonCodec in
+// shim_grpc.pb.go. Keep this file's fields and shim.proto in sync by hand;
+// running protoc against shim.proto would produce incompatible code that
+// doesn't match the rest of this package.
+package shimpb
+
+// MessageContext mirrors protocol.MessageContext: the Synapse mediation
+// context that flows into and back out of a connector operation.
+type MessageContext struct {
+	MessageId  string                 `json:"message_id,omitempty"`
+	Payload    []byte                 `json:"payload,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+	Headers    map[string]string      `json:"headers,omitempty"`
+}
+
+// ConnectorOperationRequest is sent from Synapse to the Connector.
+type ConnectorOperationRequest struct {
+	ConnectorName    string                 `json:"connector_name,omitempty"`
+	OperationName    string                 `json:"operation_name,omitempty"`
+	ConnectorConfig  map[string]interface{} `json:"connector_config,omitempty"`
+	OperationParams  map[string]interface{} `json:"operation_params,omitempty"`
+	MessageContextIn *MessageContext        `json:"message_context_in,omitempty"`
+}
+
+// ConnectorOperationResponse is sent from the Connector back to Synapse.
+type ConnectorOperationResponse struct {
+	Success           bool            `json:"success,omitempty"`
+	MessageContextOut *MessageContext `json:"message_context_out,omitempty"`
+	ErrorMessage      string          `json:"error_message,omitempty"`
+}
+
+// StreamChunk frames a large payload across multiple StreamInvoke messages
+// instead of forcing the whole thing through a single request/response.
+type StreamChunk struct {
+	// Set on the first client->server frame only.
+	Request *ConnectorOperationRequest `json:"request,omitempty"`
+	// Set on the first server->client frame only.
+	Response     *ConnectorOperationResponse `json:"response,omitempty"`
+	PayloadChunk []byte                      `json:"payload_chunk,omitempty"`
+	LastChunk    bool                        `json:"last_chunk,omitempty"`
+}
+
+type CreateRequest struct {
+	ConnectorName   string                 `json:"connector_name,omitempty"`
+	ConnectorConfig map[string]interface{} `json:"connector_config,omitempty"`
+}
+
+type CreateResponse struct {
+	Success      bool   `json:"success,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+type HealthCheckRequest struct{}
+
+type HealthCheckResponse_Status int32
+
+const (
+	HealthCheckResponse_UNKNOWN     HealthCheckResponse_Status = 0
+	HealthCheckResponse_SERVING     HealthCheckResponse_Status = 1
+	HealthCheckResponse_NOT_SERVING HealthCheckResponse_Status = 2
+)
+
+type HealthCheckResponse struct {
+	Status HealthCheckResponse_Status `json:"status,omitempty"`
+}
+
+// Event is a structured lifecycle/log record pushed from a connector to the
+// manager, correlated back to the in-flight mediation via MessageId.
+type Event_Level int32
+
+const (
+	Event_INFO  Event_Level = 0
+	Event_WARN  Event_Level = 1
+	Event_ERROR Event_Level = 2
+)
+
+func (l Event_Level) String() string {
+	switch l {
+	case Event_WARN:
+		return "WARN"
+	case Event_ERROR:
+		return "ERROR"
+	default:
+		return "INFO"
+	}
+}
+
+type Event struct {
+	MessageId string            `json:"message_id,omitempty"`
+	Level     Event_Level       `json:"level,omitempty"`
+	Kind      string            `json:"kind,omitempty"` // started|ready|operation_begin|operation_end|error|metric
+	Fields    map[string]string `json:"fields,omitempty"`
+}
+
+type ShutdownRequest struct {
+	// Grace period, in milliseconds, before the connector should force-exit.
+	GracePeriodMs int64 `json:"grace_period_ms,omitempty"`
+}
+
+type ShutdownResponse struct {
+	Accepted bool `json:"accepted,omitempty"`
+}
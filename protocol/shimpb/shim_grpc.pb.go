@@ -0,0 +1,311 @@
+// Hand-written client/server stubs for the Shim service in protocol/shim.proto.
+// See the package comment in shim.pb.go for why this isn't protoc output.
+
+package shimpb
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/status"
+)
+
+// jsonCodec marshals Shim messages as JSON over the gRPC framing. protoc's
+// binary wire codec needs the full protobuf runtime (descriptors, reflection)
+// which this module doesn't otherwise depend on, so the generated stubs are
+// wired to a small codec registered under the "json" content-subtype instead.
+// Everything else about the service (streaming, deadlines, cancellation,
+// interceptors) is standard gRPC.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                               { return "json" }
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// DialOption is the gRPC dial option every Shim client/server must use so
+// requests are framed with the jsonCodec above.
+func DialOption() grpc.DialOption {
+	return grpc.WithDefaultCallOptions(grpc.CallContentSubtype(jsonCodec{}.Name()))
+}
+
+const (
+	Shim_Create_FullMethodName       = "/shim.Shim/Create"
+	Shim_Invoke_FullMethodName       = "/shim.Shim/Invoke"
+	Shim_StreamInvoke_FullMethodName = "/shim.Shim/StreamInvoke"
+	Shim_Events_FullMethodName       = "/shim.Shim/Events"
+	Shim_HealthCheck_FullMethodName  = "/shim.Shim/HealthCheck"
+	Shim_Shutdown_FullMethodName     = "/shim.Shim/Shutdown"
+)
+
+// ShimClient is the client API for the Shim service.
+type ShimClient interface {
+	Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error)
+	Invoke(ctx context.Context, in *ConnectorOperationRequest, opts ...grpc.CallOption) (*ConnectorOperationResponse, error)
+	StreamInvoke(ctx context.Context, opts ...grpc.CallOption) (Shim_StreamInvokeClient, error)
+	Events(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (Shim_EventsClient, error)
+	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
+	Shutdown(ctx context.Context, in *ShutdownRequest, opts ...grpc.CallOption) (*ShutdownResponse, error)
+}
+
+type shimClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewShimClient(cc grpc.ClientConnInterface) ShimClient {
+	return &shimClient{cc}
+}
+
+func (c *shimClient) Create(ctx context.Context, in *CreateRequest, opts ...grpc.CallOption) (*CreateResponse, error) {
+	out := new(CreateResponse)
+	if err := c.cc.Invoke(ctx, Shim_Create_FullMethodName, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shimClient) Invoke(ctx context.Context, in *ConnectorOperationRequest, opts ...grpc.CallOption) (*ConnectorOperationResponse, error) {
+	out := new(ConnectorOperationResponse)
+	if err := c.cc.Invoke(ctx, Shim_Invoke_FullMethodName, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shimClient) StreamInvoke(ctx context.Context, opts ...grpc.CallOption) (Shim_StreamInvokeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Shim_ServiceDesc.Streams[0], Shim_StreamInvoke_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &shimStreamInvokeClient{stream}, nil
+}
+
+type Shim_StreamInvokeClient interface {
+	Send(*StreamChunk) error
+	Recv() (*StreamChunk, error)
+	grpc.ClientStream
+}
+
+type shimStreamInvokeClient struct {
+	grpc.ClientStream
+}
+
+func (x *shimStreamInvokeClient) Send(m *StreamChunk) error { return x.ClientStream.SendMsg(m) }
+func (x *shimStreamInvokeClient) Recv() (*StreamChunk, error) {
+	m := new(StreamChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *shimClient) Events(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (Shim_EventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Shim_ServiceDesc.Streams[1], Shim_Events_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &shimEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Shim_EventsClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type shimEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *shimEventsClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *shimClient) HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error) {
+	out := new(HealthCheckResponse)
+	if err := c.cc.Invoke(ctx, Shim_HealthCheck_FullMethodName, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shimClient) Shutdown(ctx context.Context, in *ShutdownRequest, opts ...grpc.CallOption) (*ShutdownResponse, error) {
+	out := new(ShutdownResponse)
+	if err := c.cc.Invoke(ctx, Shim_Shutdown_FullMethodName, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ShimServer is the server API for the Shim service. Connectors implement
+// this interface and register it with a grpc.Server via RegisterShimServer.
+type ShimServer interface {
+	Create(context.Context, *CreateRequest) (*CreateResponse, error)
+	Invoke(context.Context, *ConnectorOperationRequest) (*ConnectorOperationResponse, error)
+	StreamInvoke(Shim_StreamInvokeServer) error
+	Events(*HealthCheckRequest, Shim_EventsServer) error
+	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
+	Shutdown(context.Context, *ShutdownRequest) (*ShutdownResponse, error)
+}
+
+// UnimplementedShimServer can be embedded in a ShimServer implementation to
+// satisfy the interface before every RPC is implemented.
+type UnimplementedShimServer struct{}
+
+func (UnimplementedShimServer) Create(context.Context, *CreateRequest) (*CreateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedShimServer) Invoke(context.Context, *ConnectorOperationRequest) (*ConnectorOperationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Invoke not implemented")
+}
+func (UnimplementedShimServer) StreamInvoke(Shim_StreamInvokeServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamInvoke not implemented")
+}
+func (UnimplementedShimServer) Events(*HealthCheckRequest, Shim_EventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method Events not implemented")
+}
+func (UnimplementedShimServer) HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
+}
+func (UnimplementedShimServer) Shutdown(context.Context, *ShutdownRequest) (*ShutdownResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Shutdown not implemented")
+}
+
+type Shim_StreamInvokeServer interface {
+	Send(*StreamChunk) error
+	Recv() (*StreamChunk, error)
+	grpc.ServerStream
+}
+
+type shimStreamInvokeServer struct {
+	grpc.ServerStream
+}
+
+func (x *shimStreamInvokeServer) Send(m *StreamChunk) error { return x.ServerStream.SendMsg(m) }
+func (x *shimStreamInvokeServer) Recv() (*StreamChunk, error) {
+	m := new(StreamChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+type Shim_EventsServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type shimEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *shimEventsServer) Send(m *Event) error { return x.ServerStream.SendMsg(m) }
+
+func _Shim_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShimServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: Shim_Create_FullMethodName}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShimServer).Create(ctx, req.(*CreateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Shim_Invoke_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConnectorOperationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShimServer).Invoke(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: Shim_Invoke_FullMethodName}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShimServer).Invoke(ctx, req.(*ConnectorOperationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Shim_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShimServer).HealthCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: Shim_HealthCheck_FullMethodName}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShimServer).HealthCheck(ctx, req.(*HealthCheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Shim_Shutdown_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShutdownRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShimServer).Shutdown(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: Shim_Shutdown_FullMethodName}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShimServer).Shutdown(ctx, req.(*ShutdownRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Shim_StreamInvoke_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ShimServer).StreamInvoke(&shimStreamInvokeServer{stream})
+}
+
+func _Shim_Events_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(HealthCheckRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ShimServer).Events(m, &shimEventsServer{stream})
+}
+
+// Shim_ServiceDesc is the grpc.ServiceDesc for the Shim service.
+var Shim_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "shim.Shim",
+	HandlerType: (*ShimServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Create", Handler: _Shim_Create_Handler},
+		{MethodName: "Invoke", Handler: _Shim_Invoke_Handler},
+		{MethodName: "HealthCheck", Handler: _Shim_HealthCheck_Handler},
+		{MethodName: "Shutdown", Handler: _Shim_Shutdown_Handler},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "StreamInvoke", Handler: _Shim_StreamInvoke_Handler, ServerStreams: true, ClientStreams: true},
+		{StreamName: "Events", Handler: _Shim_Events_Handler, ServerStreams: true},
+	},
+	Metadata: "protocol/shim.proto",
+}
+
+func RegisterShimServer(s grpc.ServiceRegistrar, srv ShimServer) {
+	s.RegisterService(&Shim_ServiceDesc, srv)
+}
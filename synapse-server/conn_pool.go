@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/ThisaraWeerakoon/Synpase-Go-Connector-PoC/protocol/shimpb"
+)
+
+const (
+	defaultMaxConcurrent     = 4
+	defaultMaxIdle           = 1
+	defaultIdleTimeoutSecond = 30
+)
+
+type pooledConn struct {
+	conn     *grpc.ClientConn
+	client   shimpb.ShimClient
+	lastUsed time.Time
+}
+
+// ConnPool is a bounded pool of gRPC connections to a single connector
+// instance. Invoke used to take instance.mu.Lock() for the whole request,
+// serializing every operation even though each call is independent; the
+// pool lets up to MaxConcurrent operations run in parallel while still
+// capping how many connections (and therefore how many connector-side
+// goroutines/streams) a single instance can have open at once.
+type ConnPool struct {
+	dial func(ctx context.Context) (*grpc.ClientConn, shimpb.ShimClient, error)
+
+	maxConcurrent int
+	maxIdle       int
+	idleTimeout   time.Duration
+
+	mu      sync.Mutex
+	idle    []*pooledConn
+	numOpen int
+	waiters []chan *pooledConn
+	closed  bool
+
+	inFlight int32 // atomic: operations currently checked out of the pool
+	stopReap chan struct{}
+}
+
+// NewConnPool creates a pool that lazily dials up to maxConcurrent
+// connections on demand via dial, and reaps connections beyond maxIdle once
+// they've sat unused for idleTimeout.
+func NewConnPool(maxConcurrent, maxIdle int, idleTimeout time.Duration, dial func(ctx context.Context) (*grpc.ClientConn, shimpb.ShimClient, error)) *ConnPool {
+	if maxConcurrent <= 0 {
+		maxConcurrent = defaultMaxConcurrent
+	}
+	if maxIdle <= 0 {
+		maxIdle = defaultMaxIdle
+	}
+	if maxIdle > maxConcurrent {
+		maxIdle = maxConcurrent
+	}
+	if idleTimeout <= 0 {
+		idleTimeout = defaultIdleTimeoutSecond * time.Second
+	}
+
+	p := &ConnPool{
+		dial:          dial,
+		maxConcurrent: maxConcurrent,
+		maxIdle:       maxIdle,
+		idleTimeout:   idleTimeout,
+		stopReap:      make(chan struct{}),
+	}
+	go p.reapIdle()
+	return p
+}
+
+// InFlight returns the number of operations currently checked out of the
+// pool (i.e. in-flight Invoke calls against this connector instance).
+func (p *ConnPool) InFlight() int32 {
+	return atomic.LoadInt32(&p.inFlight)
+}
+
+// checkout returns a connection from the idle list, dials a fresh one if
+// under maxConcurrent, or blocks until one is returned/ctx is done.
+func (p *ConnPool) checkout(ctx context.Context) (*pooledConn, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("connection pool is closed")
+	}
+	if n := len(p.idle); n > 0 {
+		pc := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		p.mu.Unlock()
+		return pc, nil
+	}
+	if p.numOpen < p.maxConcurrent {
+		p.numOpen++
+		p.mu.Unlock()
+		conn, client, err := p.dial(ctx)
+		if err != nil {
+			p.mu.Lock()
+			p.numOpen--
+			p.mu.Unlock()
+			return nil, err
+		}
+		return &pooledConn{conn: conn, client: client, lastUsed: time.Now()}, nil
+	}
+
+	wait := make(chan *pooledConn, 1)
+	p.waiters = append(p.waiters, wait)
+	p.mu.Unlock()
+
+	select {
+	case pc := <-wait:
+		return pc, nil
+	case <-ctx.Done():
+		p.mu.Lock()
+		for i, w := range p.waiters {
+			if w == wait {
+				// Removed before checkin could see us: nothing will ever
+				// be sent on wait, so there's nothing left to reclaim.
+				p.waiters = append(p.waiters[:i], p.waiters[i+1:]...)
+				p.mu.Unlock()
+				return nil, ctx.Err()
+			}
+		}
+		p.mu.Unlock()
+		// checkin already popped us and is handing off a connection; take
+		// it (the send is non-blocking on this buffered channel) and return
+		// it to the pool instead of leaking it to a receiver that has
+		// already given up.
+		pc := <-wait
+		p.checkin(pc)
+		return nil, ctx.Err()
+	}
+}
+
+// checkin returns pc to the pool, or hands it directly to a waiter blocked
+// in checkout.
+func (p *ConnPool) checkin(pc *pooledConn) {
+	pc.lastUsed = time.Now()
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		pc.conn.Close()
+		return
+	}
+	if len(p.waiters) > 0 {
+		wait := p.waiters[0]
+		p.waiters = p.waiters[1:]
+		p.mu.Unlock()
+		wait <- pc
+		return
+	}
+	p.idle = append(p.idle, pc)
+	p.mu.Unlock()
+}
+
+// Invoke checks out a connection, performs the RPC, and returns the
+// connection to the pool.
+func (p *ConnPool) Invoke(ctx context.Context, req *shimpb.ConnectorOperationRequest) (*shimpb.ConnectorOperationResponse, error) {
+	pc, err := p.checkout(ctx)
+	if err != nil {
+		return nil, err
+	}
+	atomic.AddInt32(&p.inFlight, 1)
+	defer atomic.AddInt32(&p.inFlight, -1)
+	defer p.checkin(pc)
+
+	return pc.client.Invoke(ctx, req)
+}
+
+// StreamInvoke checks out a connection and opens a StreamInvoke RPC on it.
+// Unlike Invoke, the connection isn't returned to the pool until the caller
+// invokes the returned release func - a stream pins its connection for the
+// whole upload/download rather than a single request/response.
+func (p *ConnPool) StreamInvoke(ctx context.Context) (shimpb.Shim_StreamInvokeClient, func(), error) {
+	pc, err := p.checkout(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	atomic.AddInt32(&p.inFlight, 1)
+
+	stream, err := pc.client.StreamInvoke(ctx)
+	if err != nil {
+		atomic.AddInt32(&p.inFlight, -1)
+		p.checkin(pc)
+		return nil, nil, err
+	}
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			atomic.AddInt32(&p.inFlight, -1)
+			p.checkin(pc)
+		})
+	}
+	return stream, release, nil
+}
+
+// reapIdle periodically closes idle connections beyond maxIdle that have
+// been unused for longer than idleTimeout.
+func (p *ConnPool) reapIdle() {
+	ticker := time.NewTicker(p.idleTimeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stopReap:
+			return
+		case <-ticker.C:
+			p.closeExpiredIdle()
+		}
+	}
+}
+
+func (p *ConnPool) closeExpiredIdle() {
+	p.mu.Lock()
+	var keep []*pooledConn
+	var toClose []*pooledConn
+	now := time.Now()
+	for _, pc := range p.idle {
+		if len(keep) >= p.maxIdle && now.Sub(pc.lastUsed) >= p.idleTimeout {
+			toClose = append(toClose, pc)
+			p.numOpen--
+			continue
+		}
+		keep = append(keep, pc)
+	}
+	p.idle = keep
+	p.mu.Unlock()
+
+	for _, pc := range toClose {
+		pc.conn.Close()
+	}
+}
+
+// Close closes every connection the pool currently holds, open or idle.
+func (p *ConnPool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	close(p.stopReap)
+	p.mu.Unlock()
+
+	for _, pc := range idle {
+		pc.conn.Close()
+	}
+}
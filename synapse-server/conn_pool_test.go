@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/ThisaraWeerakoon/Synpase-Go-Connector-PoC/protocol/shimpb"
+)
+
+// dialNoop returns a real (but never-connecting) *grpc.ClientConn, just
+// enough for the pool to treat it like any other pooledConn and Close() it
+// without special-casing test doubles.
+func dialNoop(ctx context.Context) (*grpc.ClientConn, shimpb.ShimClient, error) {
+	conn, err := grpc.NewClient("127.0.0.1:0", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, shimpb.NewShimClient(conn), nil
+}
+
+// TestCheckoutTimeoutReclaimsConnection guards against a prior bug where a
+// checkout that timed out left its (now-unread) waiter channel in
+// p.waiters. A later checkin would hand the reclaimed connection to that
+// dead waiter and it was gone for good: not idle, not open-for-reuse, not
+// closed. Under sustained contention with timeouts the pool's usable
+// capacity ratcheted down to zero even though numOpen stayed at
+// maxConcurrent.
+func TestCheckoutTimeoutReclaimsConnection(t *testing.T) {
+	dialed := 0
+	pool := NewConnPool(1, 1, time.Minute, func(ctx context.Context) (*grpc.ClientConn, shimpb.ShimClient, error) {
+		dialed++
+		return dialNoop(ctx)
+	})
+	defer pool.Close()
+
+	holder, err := pool.checkout(context.Background())
+	if err != nil {
+		t.Fatalf("initial checkout failed: %v", err)
+	}
+
+	waiterCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := pool.checkout(waiterCtx); err == nil {
+		t.Fatal("expected waiter checkout to time out")
+	}
+
+	// The holder's checkin races with (or follows) the waiter's timeout;
+	// either way the connection must end up reusable, not stranded.
+	pool.checkin(holder)
+
+	reuseCtx, reuseCancel := context.WithTimeout(context.Background(), time.Second)
+	defer reuseCancel()
+	pc, err := pool.checkout(reuseCtx)
+	if err != nil {
+		t.Fatalf("connection was leaked by the timed-out waiter: %v", err)
+	}
+	pool.checkin(pc)
+
+	if dialed != 1 {
+		t.Fatalf("expected exactly 1 dial (maxConcurrent=1), got %d", dialed)
+	}
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net"
@@ -10,26 +12,141 @@ import (
 	"os/exec"
 	"path/filepath"
 	"sync"
+	"syscall"
 	"time"
 
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
 	"github.com/ThisaraWeerakoon/Synpase-Go-Connector-PoC/protocol"
+	"github.com/ThisaraWeerakoon/Synpase-Go-Connector-PoC/protocol/shimpb"
+)
+
+// listenFDsEnvVar tells a connector process that fd 3 is a listening socket
+// the manager already bound for it, systemd-socket-activation style.
+const listenFDsEnvVar = "SYNAPSE_LISTEN_FDS"
+
+// RestartPolicy mirrors the restart semantics of a typical process
+// supervisor (e.g. supervisord, systemd's Restart=).
+type RestartPolicy string
+
+const (
+	RestartNever     RestartPolicy = "never"
+	RestartOnFailure RestartPolicy = "on-failure"
+	RestartAlways    RestartPolicy = "always"
+)
+
+const (
+	defaultStartRetries = 3
+	defaultStartSeconds = 2 * time.Second
 )
 
+// defaultMaxInlinePayloadBytes bounds how large a MessageContext.Payload
+// Invoke will carry in a single in-memory request/response. Anything larger
+// must go through InvokeStream instead so neither end has to buffer the
+// whole payload at once.
+const defaultMaxInlinePayloadBytes = 4 << 20 // 4 MiB
+
+// streamChunkSize is the frame size InvokeStream reads/writes payload data
+// in. Matched by the connector's own streamChunkSize in main.go.
+const streamChunkSize = 32 * 1024
+
 // ConnectorDefinition holds static configuration for a connector type.
 type ConnectorDefinition struct {
-	Name                                string                 `json:"name"`
+	Name                                  string                 `json:"name"`
 	ExecutablePathRelativeToConnectorsDir string                 `json:"executable_path_relative_to_connectors_dir"`
-	DefaultPort                         int                    `json:"default_port"`
-	DefaultConfig                       map[string]interface{} `json:"default_config"`
+	DefaultPort                           int                    `json:"default_port"`
+	DefaultConfig                         map[string]interface{} `json:"default_config"`
+	RestartPolicy                         RestartPolicy          `json:"restart_policy"`
+	// StartRetries is how many times the supervisor will respawn the
+	// connector after it exits within StartSeconds of starting, before
+	// giving up and marking the instance Fatal.
+	StartRetries int `json:"start_retries"`
+	// StartSeconds is how long the connector must stay up for a restart to
+	// be considered "successful" and the retry budget to reset.
+	StartSeconds int `json:"start_seconds"`
+
+	// MaxConcurrent bounds how many gRPC connections (and therefore
+	// concurrent in-flight operations) the manager will open to a single
+	// instance of this connector.
+	MaxConcurrent int `json:"max_concurrent"`
+	// MaxIdle is the floor below which the pool stops closing idle
+	// connections.
+	MaxIdle int `json:"max_idle"`
+	// IdleTimeoutSeconds is how long a connection above MaxIdle may sit
+	// unused before the pool closes it.
+	IdleTimeoutSeconds int `json:"idle_timeout_seconds"`
+
+	// MaxInlinePayloadBytes caps how large a Payload Invoke will accept
+	// in-memory; callers with bigger payloads must use InvokeStream.
+	MaxInlinePayloadBytes int `json:"max_inline_payload_bytes"`
+}
+
+func (def ConnectorDefinition) idleTimeout() time.Duration {
+	if def.IdleTimeoutSeconds > 0 {
+		return time.Duration(def.IdleTimeoutSeconds) * time.Second
+	}
+	return defaultIdleTimeoutSecond * time.Second
 }
 
+func (def ConnectorDefinition) startRetries() int {
+	if def.StartRetries > 0 {
+		return def.StartRetries
+	}
+	return defaultStartRetries
+}
+
+func (def ConnectorDefinition) startSeconds() time.Duration {
+	if def.StartSeconds > 0 {
+		return time.Duration(def.StartSeconds) * time.Second
+	}
+	return defaultStartSeconds
+}
+
+func (def ConnectorDefinition) maxInlinePayloadBytes() int {
+	if def.MaxInlinePayloadBytes > 0 {
+		return def.MaxInlinePayloadBytes
+	}
+	return defaultMaxInlinePayloadBytes
+}
+
+// InstanceState tracks where a RunningConnectorInstance is in its lifecycle.
+type InstanceState int
+
+const (
+	StateStarting InstanceState = iota
+	StateRunning
+	StateDraining
+	StateStopped
+	StateFatal
+)
+
 // RunningConnectorInstance holds runtime information about an active connector process.
 type RunningConnectorInstance struct {
 	Definition ConnectorDefinition
 	Cmd        *exec.Cmd
-	Port       int // Actual port, might differ from default if dynamic allocation is used
-	mu         sync.Mutex
-	// In a real system, you'd have a client pool or persistent connection here
+	SocketPath string // Unix domain socket path the connector listens on.
+	Port       int    // TCP fallback port, used only if the unix socket can't be created.
+
+	// ControlConn/ControlClient is a single long-lived connection used for
+	// low-volume control-plane calls (HealthCheck, Shutdown, Events) that
+	// don't need to compete with data-plane traffic for a pool slot.
+	ControlConn   *grpc.ClientConn
+	ControlClient shimpb.ShimClient
+
+	// Pool serves the data-plane Invoke/StreamInvoke traffic. It replaces
+	// the old one-dial-per-call + whole-instance mutex model with a bounded
+	// set of persistent connections multiple operations can use at once.
+	Pool *ConnPool
+
+	mu sync.Mutex
+
+	state     InstanceState
+	startedAt time.Time
+	retryLeft int
+	// exited is closed by the supervisor once this Cmd's process has been
+	// reaped, letting health-check and drain loops stop without polling.
+	exited chan struct{}
 }
 
 // ConnectorManager manages the lifecycle and interactions with connectors.
@@ -37,15 +154,31 @@ type ConnectorManager struct {
 	connectorDefinitions map[string]ConnectorDefinition
 	runningInstances     map[string]*RunningConnectorInstance
 	connectorsBaseDir    string // e.g., "./connectors"
+	socketDir            string // where connector unix sockets are created
 	instanceMutex        sync.RWMutex
+
+	pidMu    sync.Mutex
+	pidIndex map[int]*RunningConnectorInstance
+
+	eventSubMu     sync.Mutex
+	eventSubs      map[int]*eventSubscription
+	nextEventSubID int
 }
 
 // NewConnectorManager creates a new manager and loads connector definitions.
 func NewConnectorManager(definitionsDir string, connectorsBaseDir string) (*ConnectorManager, error) {
+	socketDir, err := ioutil.TempDir("", "synapse-connectors-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
 	cm := &ConnectorManager{
 		connectorDefinitions: make(map[string]ConnectorDefinition),
 		runningInstances:     make(map[string]*RunningConnectorInstance),
 		connectorsBaseDir:    connectorsBaseDir,
+		socketDir:            socketDir,
+		pidIndex:             make(map[int]*RunningConnectorInstance),
+		eventSubs:            make(map[int]*eventSubscription),
 	}
 
 	files, err := ioutil.ReadDir(definitionsDir)
@@ -70,6 +203,8 @@ func NewConnectorManager(definitionsDir string, connectorsBaseDir string) (*Conn
 			log.Printf("ConnectorManager: Loaded definition for connector '%s'", def.Name)
 		}
 	}
+
+	cm.watchChildren()
 	return cm, nil
 }
 
@@ -79,63 +214,383 @@ func (cm *ConnectorManager) getOrStartInstance(connectorName string) (*RunningCo
 	cm.instanceMutex.RUnlock()
 
 	if exists {
-		// Basic check: If process is nil or exited, try to restart
-		if instance.Cmd == nil || (instance.Cmd.ProcessState != nil && instance.Cmd.ProcessState.Exited()) {
-			log.Printf("ConnectorManager: Instance '%s' found but process exited or nil. Attempting restart.", connectorName)
-			// Fall through to start logic by marking exists as false effectively
-		} else {
-			return instance, nil // Healthy and running
+		instance.mu.Lock()
+		state := instance.state
+		instance.mu.Unlock()
+		switch state {
+		case StateFatal:
+			return nil, fmt.Errorf("connector '%s' is marked fatal after exhausting its restart budget; manual reset required", connectorName)
+		case StateDraining:
+			return nil, fmt.Errorf("connector '%s' is draining for shutdown; rejecting new calls", connectorName)
+		case StateRunning, StateStarting:
+			return instance, nil
 		}
+		// Stopped: fall through and start a fresh instance.
 	}
-	
-	// Acquire write lock to start a new instance or restart an exited one
+
+	// Acquire write lock to start a new instance or restart an exited one.
 	cm.instanceMutex.Lock()
 	defer cm.instanceMutex.Unlock()
 
-	// Double check after acquiring write lock
+	// Double check after acquiring write lock.
 	instance, exists = cm.runningInstances[connectorName]
-     if exists && instance.Cmd != nil && (instance.Cmd.ProcessState == nil || !instance.Cmd.ProcessState.Exited()) {
-         return instance, nil // Another goroutine might have started it
-     }
+	if exists {
+		instance.mu.Lock()
+		state := instance.state
+		instance.mu.Unlock()
+		switch state {
+		case StateDraining:
+			return nil, fmt.Errorf("connector '%s' is draining for shutdown; rejecting new calls", connectorName)
+		case StateRunning, StateStarting:
+			return instance, nil // another goroutine already started it
+		}
+	}
 
 	def, ok := cm.connectorDefinitions[connectorName]
 	if !ok {
 		return nil, fmt.Errorf("connector definition for '%s' not found", connectorName)
 	}
 
+	newInstance := &RunningConnectorInstance{
+		Definition: def,
+		retryLeft:  def.startRetries(),
+	}
+	if err := cm.spawn(connectorName, newInstance); err != nil {
+		return nil, err
+	}
+
+	cm.runningInstances[connectorName] = newInstance
+	return newInstance, nil
+}
+
+// spawn starts the connector's process and dials its gRPC endpoint,
+// populating instance in place. It's used both for the initial start and to
+// respawn an instance that the supervisor decided to restart in place.
+func (cm *ConnectorManager) spawn(connectorName string, instance *RunningConnectorInstance) error {
+	def := instance.Definition
+
+	// On a respawn (crash-loop restart or monitorHealth killing a wedged
+	// connector), instance still holds the previous process's ControlConn
+	// and Pool. They're already useless - that process is gone - so close
+	// them now rather than leaking the conn and the pool's reapIdle ticker
+	// on every restart.
+	instance.mu.Lock()
+	oldConn := instance.ControlConn
+	oldPool := instance.Pool
+	instance.mu.Unlock()
+	if oldConn != nil {
+		_ = oldConn.Close()
+	}
+	if oldPool != nil {
+		oldPool.Close()
+	}
+
 	executablePath := filepath.Join(cm.connectorsBaseDir, def.ExecutablePathRelativeToConnectorsDir)
-     // Handle .exe for Windows
-     if _, err := os.Stat(executablePath); os.IsNotExist(err) {
-         if _, errExe := os.Stat(executablePath + ".exe"); errExe == nil {
-             executablePath += ".exe"
-         } else {
-             return nil, fmt.Errorf("connector executable not found at %s or %s.exe", executablePath, executablePath)
-         }
-     }
-
-
-	// For PoC, port is fixed. Real system: dynamic port allocation + registration.
-	port := def.DefaultPort
-	cmd := exec.Command(executablePath, fmt.Sprintf("-port=%d", port))
+	// Handle .exe for Windows
+	if _, err := os.Stat(executablePath); os.IsNotExist(err) {
+		if _, errExe := os.Stat(executablePath + ".exe"); errExe == nil {
+			executablePath += ".exe"
+		} else {
+			return fmt.Errorf("connector executable not found at %s or %s.exe", executablePath, executablePath)
+		}
+	}
+
+	socketPath := filepath.Join(cm.socketDir, connectorName+".sock")
+	os.Remove(socketPath)
+
+	// Bind the listening socket ourselves and hand it to the child over
+	// cmd.ExtraFiles (systemd-style socket activation). This removes the
+	// "wait a second and hope it's listening" race: the kernel accepts and
+	// queues connections on this socket the instant it's bound, whether or
+	// not the child has called Accept yet, and it sidesteps port collisions
+	// since no two instances ever race to bind the same path/port themselves.
+	unixListener, err := net.ListenUnix("unix", &net.UnixAddr{Name: socketPath, Net: "unix"})
+	if err != nil {
+		return fmt.Errorf("failed to preopen socket for connector '%s': %w", connectorName, err)
+	}
+	// The child keeps listening on this socket long after we close our copy
+	// of the fd below; don't let Close() unlink the path out from under it.
+	unixListener.SetUnlinkOnClose(false)
+	listenerFile, err := unixListener.File()
+	if err != nil {
+		unixListener.Close()
+		return fmt.Errorf("failed to obtain fd for preopened socket: %w", err)
+	}
+	// File() dup()s the fd for listenerFile; the manager doesn't need to
+	// Accept() on this copy itself, so close it once the child has its own.
+	unixListener.Close()
+
+	cmd := exec.Command(executablePath)
+	cmd.ExtraFiles = []*os.File{listenerFile}
+	cmd.Env = append(os.Environ(), fmt.Sprintf("%s=1", listenFDsEnvVar))
 	cmd.Stdout = os.Stdout // For PoC, pipe to server's stdio
 	cmd.Stderr = os.Stderr
 
 	log.Printf("ConnectorManager: Starting connector '%s' with command: %s", connectorName, cmd.String())
 	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start connector '%s': %w", connectorName, err)
+		listenerFile.Close()
+		return fmt.Errorf("failed to start connector '%s': %w", connectorName, err)
 	}
+	listenerFile.Close() // child has its own dup'd copy now
 
-	newInstance := &RunningConnectorInstance{
-		Definition: def,
-		Cmd:        cmd,
-		Port:       port,
+	instance.mu.Lock()
+	instance.Cmd = cmd
+	instance.SocketPath = socketPath
+	instance.Port = def.DefaultPort
+	instance.state = StateStarting
+	instance.startedAt = time.Now()
+	instance.exited = make(chan struct{})
+	instance.mu.Unlock()
+
+	target, conn, client, err := resolveConnectorTarget(instance)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		return fmt.Errorf("failed to connect to connector '%s': %w", connectorName, err)
 	}
-	cm.runningInstances[connectorName] = newInstance
 
-	// Crude readiness check: wait a bit. Real system: health check endpoint on connector.
-	time.Sleep(1 * time.Second)
-	log.Printf("ConnectorManager: Connector '%s' started (PID: %d) on port %d", connectorName, cmd.Process.Pid, port)
-	return newInstance, nil
+	pool := NewConnPool(def.MaxConcurrent, def.MaxIdle, def.idleTimeout(), func(ctx context.Context) (*grpc.ClientConn, shimpb.ShimClient, error) {
+		return dialTarget(ctx, target)
+	})
+
+	instance.mu.Lock()
+	instance.ControlConn = conn
+	instance.ControlClient = client
+	instance.Pool = pool
+	instance.state = StateRunning
+	instance.mu.Unlock()
+
+	cm.registerPid(cmd.Process.Pid, instance)
+	cm.watchExit(cmd)
+	go cm.monitorHealth(connectorName, instance)
+	go cm.tailEvents(connectorName, instance)
+
+	log.Printf("ConnectorManager: Connector '%s' started (PID: %d) on socket %s", connectorName, cmd.Process.Pid, socketPath)
+	return nil
+}
+
+func (cm *ConnectorManager) registerPid(pid int, instance *RunningConnectorInstance) {
+	cm.pidMu.Lock()
+	cm.pidIndex[pid] = instance
+	cm.pidMu.Unlock()
+	cm.registerChildOwner(pid)
+}
+
+// handleChildExit is invoked by the platform-specific supervisor
+// (see connector_supervisor_*.go) once it learns a connector process has
+// exited. cleanExit is true when the process exited with status 0.
+func (cm *ConnectorManager) handleChildExit(pid int, cleanExit bool) {
+	cm.pidMu.Lock()
+	instance, ok := cm.pidIndex[pid]
+	if ok {
+		delete(cm.pidIndex, pid)
+	}
+	cm.pidMu.Unlock()
+	if !ok {
+		return // not a connector child we're tracking (or already reaped)
+	}
+
+	instance.mu.Lock()
+	name := instance.Definition.Name
+	wasDraining := instance.state == StateDraining
+	if instance.exited != nil {
+		close(instance.exited)
+		instance.exited = nil
+	}
+	ranFor := time.Since(instance.startedAt)
+	if ranFor >= instance.Definition.startSeconds() {
+		instance.retryLeft = instance.Definition.startRetries() // stable run, reset the budget
+	} else {
+		instance.retryLeft--
+	}
+	retryLeft := instance.retryLeft
+	policy := instance.Definition.RestartPolicy
+	instance.state = StateStopped
+	instance.mu.Unlock()
+
+	log.Printf("ConnectorManager: Connector '%s' (PID %d) exited (clean=%t) after %s", name, pid, cleanExit, ranFor.Round(time.Millisecond))
+
+	if wasDraining {
+		return // expected exit as part of a graceful shutdown
+	}
+
+	shouldRestart := !cleanExit // sensible default if no policy is configured: on-failure
+	switch policy {
+	case RestartAlways:
+		shouldRestart = true
+	case RestartOnFailure, "":
+		shouldRestart = !cleanExit
+	case RestartNever:
+		shouldRestart = false
+	}
+
+	if !shouldRestart {
+		cm.instanceMutex.Lock()
+		if cur, exists := cm.runningInstances[name]; exists && cur == instance {
+			delete(cm.runningInstances, name)
+		}
+		cm.instanceMutex.Unlock()
+		return
+	}
+
+	if retryLeft <= 0 {
+		instance.mu.Lock()
+		instance.state = StateFatal
+		instance.mu.Unlock()
+		log.Printf("ConnectorManager: Connector '%s' exhausted its restart budget (%d attempts); marking Fatal", name, instance.Definition.startRetries())
+		return
+	}
+
+	backoff := backoffDelay(instance.Definition.startRetries() - retryLeft)
+	log.Printf("ConnectorManager: Restarting connector '%s' in %s (retries left: %d)", name, backoff, retryLeft)
+	time.AfterFunc(backoff, func() {
+		cm.instanceMutex.Lock()
+		defer cm.instanceMutex.Unlock()
+		if cur, exists := cm.runningInstances[name]; !exists || cur != instance {
+			return // superseded by a manual restart/shutdown while we waited
+		}
+		if err := cm.spawn(name, instance); err != nil {
+			log.Printf("ConnectorManager: Failed to restart connector '%s': %v", name, err)
+		}
+	})
+}
+
+// backoffDelay returns an exponential backoff, capped at 30s, for the given
+// (zero-based) restart attempt number.
+func backoffDelay(attempt int) time.Duration {
+	if attempt < 0 {
+		attempt = 0
+	}
+	d := time.Duration(1<<uint(attempt)) * time.Second
+	if d > 30*time.Second {
+		d = 30 * time.Second
+	}
+	return d
+}
+
+// monitorHealth periodically pings the connector's HealthCheck RPC and kills
+// the process for a supervisor-driven restart after consecutive failures.
+func (cm *ConnectorManager) monitorHealth(connectorName string, instance *RunningConnectorInstance) {
+	const (
+		healthInterval    = 5 * time.Second
+		healthTimeout     = 2 * time.Second
+		maxHealthFailures = 3
+	)
+
+	instance.mu.Lock()
+	exited := instance.exited
+	client := instance.ControlClient
+	instance.mu.Unlock()
+
+	ticker := time.NewTicker(healthInterval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		select {
+		case <-exited:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), healthTimeout)
+			_, err := client.HealthCheck(ctx, &shimpb.HealthCheckRequest{})
+			cancel()
+			if err == nil {
+				failures = 0
+				continue
+			}
+
+			failures++
+			log.Printf("ConnectorManager: Health check for '%s' failed (%d/%d): %v", connectorName, failures, maxHealthFailures, err)
+			if failures < maxHealthFailures {
+				continue
+			}
+
+			log.Printf("ConnectorManager: Connector '%s' failed %d consecutive health checks; killing for restart", connectorName, failures)
+			instance.mu.Lock()
+			if instance.Cmd != nil && instance.Cmd.Process != nil {
+				_ = instance.Cmd.Process.Kill()
+			}
+			instance.mu.Unlock()
+			return
+		}
+	}
+}
+
+// resolveConnectorTarget dials a freshly started connector, preferring its
+// Unix domain socket and falling back to the DefaultPort TCP listener if the
+// socket never comes up (e.g. the connector binary predates -socket
+// support). The returned target is reused by the pool to open every
+// additional connection it needs.
+func resolveConnectorTarget(instance *RunningConnectorInstance) (target string, conn *grpc.ClientConn, client shimpb.ShimClient, err error) {
+	target = "unix:" + instance.SocketPath
+	conn, client, err = dialTarget(context.Background(), target)
+	if err == nil {
+		return target, conn, client, nil
+	}
+	if instance.Port == 0 {
+		return "", nil, nil, err
+	}
+	log.Printf("ConnectorManager: Unix socket %s not reachable (%v), falling back to TCP port %d", instance.SocketPath, err, instance.Port)
+	target = fmt.Sprintf("127.0.0.1:%d", instance.Port)
+	conn, client, err = dialTarget(context.Background(), target)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return target, conn, client, nil
+}
+
+// dialTarget opens a single gRPC connection to target (a "unix:<path>" or
+// "host:port" address).
+func dialTarget(ctx context.Context, target string) (*grpc.ClientConn, shimpb.ShimClient, error) {
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		shimpb.DialOption(),
+		grpc.WithBlock(),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, target, dialOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, shimpb.NewShimClient(conn), nil
+}
+
+// mergeConnectorConfig returns a fresh map holding defaults overlaid with
+// override. Copying rather than mutating defaults in place matters now that
+// the pool allows concurrent Invoke calls on the same instance: two requests
+// merging overrides into a shared map at once would race and could leak one
+// call's override into another's config.
+func mergeConnectorConfig(defaults, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(defaults)+len(override))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// connectorConfigMaxInlineKey is a well-known ConnectorConfig entry Invoke
+// sets so the connector can apply the same MaxInlinePayloadBytes guard to
+// its response (e.g. a "read" op) that Invoke already applies to the
+// request payload - otherwise a large file read still gets buffered whole
+// by the connector before the oversized response even reaches the guard.
+const connectorConfigMaxInlineKey = "maxInlinePayloadBytes"
+
+func toProtocolResponse(resp *shimpb.ConnectorOperationResponse) protocol.ConnectorOperationResponse {
+	out := protocol.ConnectorOperationResponse{Success: resp.Success, ErrorMessage: resp.ErrorMessage}
+	if resp.MessageContextOut != nil {
+		out.MessageContextOut = protocol.MessageContext{
+			MessageID:  resp.MessageContextOut.MessageId,
+			Payload:    resp.MessageContextOut.Payload,
+			Properties: resp.MessageContextOut.Properties,
+			Headers:    resp.MessageContextOut.Headers,
+		}
+	}
+	return out
 }
 
 // Invoke sends an operation to a connector.
@@ -152,69 +607,294 @@ func (cm *ConnectorManager) Invoke(
 		return protocol.ConnectorOperationResponse{Success: false, ErrorMessage: err.Error()}, err
 	}
 
-	instance.mu.Lock() // Serialize operations on a single instance for simplicity
-	defer instance.mu.Unlock()
+	maxBytes := instance.Definition.maxInlinePayloadBytes()
+	if len(msgCtxIn.Payload) > maxBytes {
+		err := fmt.Errorf("payload of %d bytes exceeds MaxInlinePayloadBytes (%d) for connector '%s'; use InvokeStream", len(msgCtxIn.Payload), maxBytes, connectorName)
+		return protocol.ConnectorOperationResponse{Success: false, ErrorMessage: err.Error()}, err
+	}
 
-	conn, err := net.DialTimeout("tcp", fmt.Sprintf("127.0.0.1:%d", instance.Port), 5*time.Second)
+	currentConnectorConfig := mergeConnectorConfig(instance.Definition.DefaultConfig, connectorConfigOverride)
+	// Tell the connector the same limit so it can reject an oversized
+	// response (e.g. "read" on a huge file) before buffering it, rather
+	// than only finding out once the reply is too big for gRPC to send.
+	currentConnectorConfig[connectorConfigMaxInlineKey] = maxBytes
+
+	req := &shimpb.ConnectorOperationRequest{
+		ConnectorName:   connectorName,
+		OperationName:   operationName,
+		ConnectorConfig: currentConnectorConfig,
+		OperationParams: operationParams,
+		MessageContextIn: &shimpb.MessageContext{
+			MessageId:  msgCtxIn.MessageID,
+			Payload:    msgCtxIn.Payload,
+			Properties: msgCtxIn.Properties,
+			Headers:    msgCtxIn.Headers,
+		},
+	}
+
+	log.Printf("ConnectorManager: Sending request to %s for op %s", connectorName, operationName)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resp, err := instance.Pool.Invoke(ctx, req)
 	if err != nil {
-		// Mark for potential restart if connection fails
-		if instance.Cmd != nil && instance.Cmd.ProcessState == nil { // If process not already marked exited
-			log.Printf("ConnectorManager: Failed to connect to '%s' (PID: %d). Marking for potential restart.", connectorName, instance.Cmd.Process.Pid)
-			// A more robust system would kill and restart or have a backoff
-		}
-		return protocol.ConnectorOperationResponse{Success: false, ErrorMessage: fmt.Sprintf("failed to connect to connector '%s': %v", connectorName, err)}, err
+		return protocol.ConnectorOperationResponse{Success: false, ErrorMessage: fmt.Sprintf("failed to invoke connector '%s': %v", connectorName, err)}, err
 	}
-	defer conn.Close()
 
-	currentConnectorConfig := instance.Definition.DefaultConfig
-	if connectorConfigOverride != nil { // Merge/override
-		if currentConnectorConfig == nil {
-			currentConnectorConfig = make(map[string]interface{})
-		}
-		for k, v := range connectorConfigOverride {
-			currentConnectorConfig[k] = v
-		}
+	out := toProtocolResponse(resp)
+	log.Printf("ConnectorManager: Received response from %s for op %s. Success: %t", connectorName, operationName, out.Success)
+	return out, nil
+}
+
+// InvokeStream is Invoke's counterpart for payloads too large (or too
+// open-ended) to buffer in memory. in, if non-nil, is chunked to the
+// connector as the operation's payload; the returned io.ReadCloser streams
+// whatever payload the connector sends back (e.g. a file read) and must be
+// closed by the caller. The response's MessageContextOut.Payload is left
+// empty - the same bytes are available by reading from the returned
+// io.ReadCloser instead.
+func (cm *ConnectorManager) InvokeStream(
+	ctx context.Context,
+	connectorName string,
+	operationName string,
+	connectorConfigOverride map[string]interface{},
+	operationParams map[string]interface{},
+	msgCtxIn protocol.MessageContext,
+	in io.Reader,
+) (io.ReadCloser, protocol.ConnectorOperationResponse, error) {
+
+	instance, err := cm.getOrStartInstance(connectorName)
+	if err != nil {
+		return nil, protocol.ConnectorOperationResponse{Success: false, ErrorMessage: err.Error()}, err
 	}
 
-	req := protocol.ConnectorOperationRequest{
+	currentConnectorConfig := mergeConnectorConfig(instance.Definition.DefaultConfig, connectorConfigOverride)
+
+	stream, release, err := instance.Pool.StreamInvoke(ctx)
+	if err != nil {
+		return nil, protocol.ConnectorOperationResponse{Success: false, ErrorMessage: err.Error()}, err
+	}
+
+	req := &shimpb.ConnectorOperationRequest{
 		ConnectorName:   connectorName,
 		OperationName:   operationName,
 		ConnectorConfig: currentConnectorConfig,
 		OperationParams: operationParams,
-		MessageContextIn: msgCtxIn,
+		MessageContextIn: &shimpb.MessageContext{
+			MessageId:  msgCtxIn.MessageID,
+			Properties: msgCtxIn.Properties,
+			Headers:    msgCtxIn.Headers,
+			// Payload travels as PayloadChunk frames below, not inline here.
+		},
+	}
+	if err := stream.Send(&shimpb.StreamChunk{Request: req}); err != nil {
+		release()
+		return nil, protocol.ConnectorOperationResponse{Success: false, ErrorMessage: err.Error()}, err
 	}
 
-	encoder := json.NewEncoder(conn)
-	decoder := json.NewDecoder(conn)
-
-	log.Printf("ConnectorManager: Sending request to %s for op %s", connectorName, operationName)
-	if err := encoder.Encode(req); err != nil {
-		return protocol.ConnectorOperationResponse{Success: false, ErrorMessage: fmt.Sprintf("failed to send request to connector '%s': %v", connectorName, err)}, err
+	if in != nil {
+		buf := make([]byte, streamChunkSize)
+		for {
+			n, rerr := in.Read(buf)
+			if n > 0 {
+				chunk := make([]byte, n)
+				copy(chunk, buf[:n])
+				if serr := stream.Send(&shimpb.StreamChunk{PayloadChunk: chunk}); serr != nil {
+					release()
+					return nil, protocol.ConnectorOperationResponse{Success: false, ErrorMessage: serr.Error()}, serr
+				}
+			}
+			if rerr == io.EOF {
+				break
+			}
+			if rerr != nil {
+				release()
+				return nil, protocol.ConnectorOperationResponse{Success: false, ErrorMessage: rerr.Error()}, rerr
+			}
+		}
+	}
+	if err := stream.Send(&shimpb.StreamChunk{LastChunk: true}); err != nil {
+		release()
+		return nil, protocol.ConnectorOperationResponse{Success: false, ErrorMessage: err.Error()}, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		release()
+		return nil, protocol.ConnectorOperationResponse{Success: false, ErrorMessage: err.Error()}, err
 	}
 
-	var resp protocol.ConnectorOperationResponse
-	if err := decoder.Decode(&resp); err != nil {
-		return protocol.ConnectorOperationResponse{Success: false, ErrorMessage: fmt.Sprintf("failed to decode response from connector '%s': %v", connectorName, err)}, err
+	first, err := stream.Recv()
+	if err != nil {
+		release()
+		return nil, protocol.ConnectorOperationResponse{Success: false, ErrorMessage: err.Error()}, err
+	}
+	if first.Response == nil {
+		release()
+		err := fmt.Errorf("connector '%s' did not send a response header for op %s", connectorName, operationName)
+		return nil, protocol.ConnectorOperationResponse{Success: false, ErrorMessage: err.Error()}, err
 	}
-	log.Printf("ConnectorManager: Received response from %s for op %s. Success: %t", connectorName, operationName, resp.Success)
-	return resp, nil
+	resp := toProtocolResponse(first.Response)
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer release()
+		if len(first.PayloadChunk) > 0 {
+			if _, werr := pw.Write(first.PayloadChunk); werr != nil {
+				pw.CloseWithError(werr)
+				return
+			}
+		}
+		if first.LastChunk {
+			pw.Close()
+			return
+		}
+		for {
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				pw.Close()
+				return
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if len(chunk.PayloadChunk) > 0 {
+				if _, werr := pw.Write(chunk.PayloadChunk); werr != nil {
+					pw.CloseWithError(werr)
+					return
+				}
+			}
+			if chunk.LastChunk {
+				pw.Close()
+				return
+			}
+		}
+	}()
+
+	return pr, resp, nil
 }
 
-// ShutdownAll terminates all running connector instances.
-func (cm *ConnectorManager) ShutdownAll() {
+// shutdownGracePeriod is how long ShutdownAll waits after sending SIGTERM
+// before escalating to SIGKILL.
+const shutdownGracePeriod = 5 * time.Second
+
+// drainPollInterval is how often ShutdownAll polls an instance's in-flight
+// operation count while waiting for it to drain to zero.
+const drainPollInterval = 50 * time.Millisecond
+
+// shutdownRPCTimeout bounds how long shutdownInstance waits for the
+// connector to accept the Shutdown RPC itself, not for the drain it starts
+// to finish - the RPC returns as soon as draining has begun.
+const shutdownRPCTimeout = 2 * time.Second
+
+// ShutdownAll drains and terminates every running connector instance.
+// Instances are marked Draining immediately so concurrent Invoke/
+// InvokeStream calls start failing fast instead of racing the shutdown.
+// ShutdownAll then waits, up to ctx's deadline, for each instance's
+// in-flight operations to finish before signaling the process; a slow
+// instance doesn't block the others since they're drained concurrently.
+func (cm *ConnectorManager) ShutdownAll(ctx context.Context) {
 	cm.instanceMutex.Lock()
-	defer cm.instanceMutex.Unlock()
-	log.Println("ConnectorManager: Shutting down all connector instances...")
+	instances := make(map[string]*RunningConnectorInstance, len(cm.runningInstances))
 	for name, instance := range cm.runningInstances {
-		if instance.Cmd != nil && instance.Cmd.Process != nil {
-			log.Printf("ConnectorManager: Terminating connector '%s' (PID: %d)", name, instance.Cmd.Process.Pid)
-			if err := instance.Cmd.Process.Signal(os.Interrupt); err != nil { // SIGINT
-				log.Printf("ConnectorManager: Failed to send SIGINT to %s, attempting SIGKILL: %v", name, err)
-				_ = instance.Cmd.Process.Kill()
+		instances[name] = instance
+		instance.mu.Lock()
+		instance.state = StateDraining // tell getOrStartInstance/handleChildExit this exit is expected
+		instance.mu.Unlock()
+	}
+	cm.instanceMutex.Unlock()
+
+	log.Println("ConnectorManager: Shutting down all connector instances...")
+
+	var wg sync.WaitGroup
+	for name, instance := range instances {
+		wg.Add(1)
+		go func(name string, instance *RunningConnectorInstance) {
+			defer wg.Done()
+			cm.shutdownInstance(ctx, name, instance)
+		}(name, instance)
+	}
+	wg.Wait()
+
+	cm.instanceMutex.Lock()
+	cm.runningInstances = make(map[string]*RunningConnectorInstance)
+	cm.instanceMutex.Unlock()
+	os.RemoveAll(cm.socketDir)
+}
+
+// waitForDrain blocks until instance's pool reports zero in-flight
+// operations or ctx is done, whichever comes first.
+func (cm *ConnectorManager) waitForDrain(ctx context.Context, name string, instance *RunningConnectorInstance) {
+	instance.mu.Lock()
+	pool := instance.Pool
+	instance.mu.Unlock()
+	if pool == nil {
+		return
+	}
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for {
+		if pool.InFlight() == 0 {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			log.Printf("ConnectorManager: Shutdown deadline reached for '%s' with %d in-flight operation(s) still active", name, pool.InFlight())
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// shutdownInstance drains, then terminates, a single connector instance:
+// SIGTERM first, escalating to SIGKILL if the process hasn't exited within
+// shutdownGracePeriod.
+func (cm *ConnectorManager) shutdownInstance(ctx context.Context, name string, instance *RunningConnectorInstance) {
+	cm.waitForDrain(ctx, name, instance)
+
+	// Ask the connector to drain over the control connection first, so it
+	// gets a clean chance to stop accepting work on its own; SIGTERM below
+	// still runs unconditionally as a fallback in case the RPC couldn't be
+	// delivered (connection already down, connector predates Shutdown, etc).
+	if instance.ControlClient != nil {
+		rpcCtx, cancel := context.WithTimeout(context.Background(), shutdownRPCTimeout)
+		_, err := instance.ControlClient.Shutdown(rpcCtx, &shimpb.ShutdownRequest{GracePeriodMs: shutdownGracePeriod.Milliseconds()})
+		cancel()
+		if err != nil {
+			log.Printf("ConnectorManager: Shutdown RPC to '%s' failed (%v); falling back to SIGTERM/SIGKILL", name, err)
+		}
+	}
+
+	if instance.ControlConn != nil {
+		_ = instance.ControlConn.Close()
+	}
+	if instance.Pool != nil {
+		instance.Pool.Close()
+	}
+
+	instance.mu.Lock()
+	cmd := instance.Cmd
+	exited := instance.exited
+	instance.mu.Unlock()
+
+	if cmd != nil && cmd.Process != nil {
+		log.Printf("ConnectorManager: Terminating connector '%s' (PID: %d)", name, cmd.Process.Pid)
+		if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+			log.Printf("ConnectorManager: Failed to send SIGTERM to %s, attempting SIGKILL: %v", name, err)
+			_ = cmd.Process.Kill()
+		}
+		if exited != nil {
+			select {
+			case <-exited: // reaped cleanly within the grace period
+			case <-time.After(shutdownGracePeriod):
+				log.Printf("ConnectorManager: Connector '%s' did not exit within %s of SIGTERM; sending SIGKILL", name, shutdownGracePeriod)
+				_ = cmd.Process.Kill()
+				<-exited // wait for the supervisor to reap it, rather than racing cmd.Wait() against it
 			}
-			instance.Cmd.Wait() // Wait for the process to exit
-			log.Printf("ConnectorManager: Connector '%s' shut down.", name)
 		}
+		log.Printf("ConnectorManager: Connector '%s' shut down.", name)
 	}
-	cm.runningInstances = make(map[string]*RunningConnectorInstance)
-}
\ No newline at end of file
+	if instance.SocketPath != "" {
+		os.Remove(instance.SocketPath)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ThisaraWeerakoon/Synpase-Go-Connector-PoC/protocol"
+)
+
+// TestInvokeRejectsOversizedRead guards against a read whose result exceeds
+// MaxInlinePayloadBytes being buffered whole by the connector and only
+// failing once gRPC refuses to send the oversized reply. The connector
+// should stat the file and reject it up front, the same way Invoke already
+// rejects an oversized inbound payload.
+func TestInvokeRejectsOversizedRead(t *testing.T) {
+	dataDir := t.TempDir()
+	bigPath := filepath.Join(dataDir, "big.bin")
+	if err := os.WriteFile(bigPath, make([]byte, 10*1024*1024), 0644); err != nil {
+		t.Fatalf("failed to seed test file: %v", err)
+	}
+
+	defsDir := t.TempDir()
+	def := fmt.Sprintf(`{
+		"name": "SimpleFileConnector",
+		"executable_path_relative_to_connectors_dir": "simple-file-connector/simple-file-connector",
+		"default_config": {"baseDirectory": %q}
+	}`, dataDir)
+	if err := os.WriteFile(filepath.Join(defsDir, "simple-file-connector.json"), []byte(def), 0644); err != nil {
+		t.Fatalf("failed to write connector definition: %v", err)
+	}
+
+	cm, err := NewConnectorManager(defsDir, "../connectors")
+	if err != nil {
+		t.Fatalf("failed to create ConnectorManager: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		cm.ShutdownAll(ctx)
+	})
+
+	resp, err := cm.Invoke("SimpleFileConnector", "read", nil, map[string]interface{}{"filename": "big.bin"}, protocol.MessageContext{MessageID: "m1"})
+	if err != nil {
+		t.Fatalf("Invoke itself returned an error (expected a clean failure response instead): %v", err)
+	}
+	if resp.Success {
+		t.Fatal("expected the oversized read to fail")
+	}
+	if !strings.Contains(resp.ErrorMessage, "StreamInvoke") {
+		t.Fatalf("expected error message to point callers at StreamInvoke, got: %s", resp.ErrorMessage)
+	}
+}
@@ -0,0 +1,79 @@
+//go:build !windows
+
+package main
+
+import (
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// watchExit is a no-op here: the SIGCHLD handler installed by watchChildren
+// reaps every child, including this one.
+func (cm *ConnectorManager) watchExit(cmd *exec.Cmd) {}
+
+// childOwners maps a spawned connector PID to the ConnectorManager that
+// spawned it. SIGCHLD and Wait4(-1, ...) are both process-global - there's
+// exactly one reaper goroutine for the whole process (see watchChildren),
+// shared by every ConnectorManager instead of each installing its own, so
+// that a PID is always routed to the manager that actually owns it rather
+// than whichever manager's private Wait4 loop happened to win the race.
+var (
+	childOwnersMu sync.Mutex
+	childOwners   = make(map[int]*ConnectorManager)
+)
+
+// registerChildOwner records that pid belongs to cm, for the shared reaper
+// to route its exit to. Called once the process has actually been started.
+func (cm *ConnectorManager) registerChildOwner(pid int) {
+	childOwnersMu.Lock()
+	childOwners[pid] = cm
+	childOwnersMu.Unlock()
+}
+
+var childReaperOnce sync.Once
+
+// watchChildren ensures the single process-wide SIGCHLD reaper is running.
+// It's safe to call once per ConnectorManager: only the first call actually
+// installs the signal handler and starts the reaping goroutine.
+func (cm *ConnectorManager) watchChildren() {
+	childReaperOnce.Do(startChildReaper)
+}
+
+// startChildReaper installs a SIGCHLD handler so managers learn a connector
+// died the instant the kernel reaps it, instead of on the next failed
+// Invoke/health-check. Wait4(-1, ...) drains every exited child in one pass
+// since SIGCHLD delivery can coalesce multiple exits into a single signal,
+// then routes each reaped PID to its owning manager via childOwners.
+func startChildReaper() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGCHLD)
+
+	go func() {
+		for range sigCh {
+			for {
+				var wstatus syscall.WaitStatus
+				pid, err := syscall.Wait4(-1, &wstatus, syscall.WNOHANG, nil)
+				if err != nil || pid <= 0 {
+					break
+				}
+				cleanExit := wstatus.Exited() && wstatus.ExitStatus() == 0
+				log.Printf("ConnectorManager: Reaped child PID %d (exit status: %d)", pid, wstatus.ExitStatus())
+
+				childOwnersMu.Lock()
+				owner := childOwners[pid]
+				delete(childOwners, pid)
+				childOwnersMu.Unlock()
+
+				if owner == nil {
+					log.Printf("ConnectorManager: no manager registered for reaped PID %d; ignoring", pid)
+					continue
+				}
+				owner.handleChildExit(pid, cleanExit)
+			}
+		}
+	}()
+}
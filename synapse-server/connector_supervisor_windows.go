@@ -0,0 +1,25 @@
+//go:build windows
+
+package main
+
+import "os/exec"
+
+// watchChildren is a no-op on Windows, which has no SIGCHLD. Exit detection
+// instead happens per-process in watchExit.
+func (cm *ConnectorManager) watchChildren() {}
+
+// registerChildOwner is a no-op on Windows: watchExit already tracks each
+// child's exit through its own dedicated goroutine, so there's no shared
+// process-wide reaper to route PIDs through (see connector_supervisor_unix.go).
+func (cm *ConnectorManager) registerChildOwner(pid int) {}
+
+// watchExit blocks on cmd.Wait() in its own goroutine and reports the exit
+// through handleChildExit, since Windows has no equivalent of SIGCHLD to
+// reap children asynchronously.
+func (cm *ConnectorManager) watchExit(cmd *exec.Cmd) {
+	pid := cmd.Process.Pid
+	go func() {
+		cleanExit := cmd.Wait() == nil
+		cm.handleChildExit(pid, cleanExit)
+	}()
+}
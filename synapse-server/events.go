@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/ThisaraWeerakoon/Synpase-Go-Connector-PoC/protocol/shimpb"
+)
+
+// Event is a lifecycle/log record tailed from a connector's Events RPC,
+// correlated back to the in-flight mediation that produced it via
+// MessageID.
+type Event struct {
+	ConnectorName string
+	MessageID     string
+	Level         string
+	Kind          string // started|ready|operation_begin|operation_end|error|metric
+	Fields        map[string]string
+}
+
+// EventFilter narrows a Subscribe call to events from a specific connector
+// and/or a specific in-flight message. A zero-value EventFilter matches
+// everything.
+type EventFilter struct {
+	ConnectorName string
+	MessageID     string
+}
+
+func (f EventFilter) matches(e Event) bool {
+	if f.ConnectorName != "" && f.ConnectorName != e.ConnectorName {
+		return false
+	}
+	if f.MessageID != "" && f.MessageID != e.MessageID {
+		return false
+	}
+	return true
+}
+
+type eventSubscription struct {
+	filter EventFilter
+	ch     chan Event
+}
+
+// Subscribe returns a channel of events matching filter and a cancel func
+// to stop delivery and release the channel. Callers (the Synapse server, or
+// future admin/metrics endpoints) use this to tail connector activity
+// instead of grepping interleaved stdout from dozens of connector processes.
+func (cm *ConnectorManager) Subscribe(filter EventFilter) (<-chan Event, func()) {
+	cm.eventSubMu.Lock()
+	defer cm.eventSubMu.Unlock()
+
+	id := cm.nextEventSubID
+	cm.nextEventSubID++
+	sub := &eventSubscription{filter: filter, ch: make(chan Event, 64)}
+	cm.eventSubs[id] = sub
+
+	cancel := func() {
+		cm.eventSubMu.Lock()
+		defer cm.eventSubMu.Unlock()
+		if _, ok := cm.eventSubs[id]; ok {
+			delete(cm.eventSubs, id)
+			close(sub.ch)
+		}
+	}
+	return sub.ch, cancel
+}
+
+func (cm *ConnectorManager) publishEvent(connectorName string, ev *shimpb.Event) {
+	e := Event{
+		ConnectorName: connectorName,
+		MessageID:     ev.MessageId,
+		Level:         ev.Level.String(),
+		Kind:          ev.Kind,
+		Fields:        ev.Fields,
+	}
+
+	cm.eventSubMu.Lock()
+	defer cm.eventSubMu.Unlock()
+	for _, sub := range cm.eventSubs {
+		if !sub.filter.matches(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default: // slow subscriber; drop rather than block event delivery
+		}
+	}
+}
+
+// tailEvents subscribes to a connector instance's Events RPC and republishes
+// every record to cm's subscribers until the instance's process exits.
+func (cm *ConnectorManager) tailEvents(connectorName string, instance *RunningConnectorInstance) {
+	instance.mu.Lock()
+	client := instance.ControlClient
+	exited := instance.exited
+	instance.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if exited != nil {
+		go func() {
+			select {
+			case <-exited:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	stream, err := client.Events(ctx, &shimpb.HealthCheckRequest{})
+	if err != nil {
+		log.Printf("ConnectorManager: Failed to subscribe to events for '%s': %v", connectorName, err)
+		return
+	}
+	for {
+		ev, err := stream.Recv()
+		if err != nil {
+			return
+		}
+		cm.publishEvent(connectorName, ev)
+	}
+}
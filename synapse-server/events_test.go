@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestSubscribeObservesStartupEvents guards against a connector's "started"
+// and "ready" events being dropped because they're published while main is
+// still setting up the gRPC server - strictly before the manager could have
+// dialed it and subscribed. Subscribe is called before the instance even
+// exists, the same way a caller racing getOrStartInstance would, to make
+// sure those two events are still observed rather than silently missed.
+func TestSubscribeObservesStartupEvents(t *testing.T) {
+	defsDir := t.TempDir()
+	def := fmt.Sprintf(`{
+		"name": "SimpleFileConnector",
+		"executable_path_relative_to_connectors_dir": "simple-file-connector/simple-file-connector",
+		"default_config": {"baseDirectory": %q}
+	}`, t.TempDir())
+	if err := os.WriteFile(filepath.Join(defsDir, "simple-file-connector.json"), []byte(def), 0644); err != nil {
+		t.Fatalf("failed to write connector definition: %v", err)
+	}
+
+	cm, err := NewConnectorManager(defsDir, "../connectors")
+	if err != nil {
+		t.Fatalf("failed to create ConnectorManager: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		cm.ShutdownAll(ctx)
+	})
+
+	events, cancel := cm.Subscribe(EventFilter{ConnectorName: "SimpleFileConnector"})
+	defer cancel()
+
+	if _, err := cm.getOrStartInstance("SimpleFileConnector"); err != nil {
+		t.Fatalf("failed to start connector: %v", err)
+	}
+
+	seen := map[string]bool{}
+	timeout := time.After(5 * time.Second)
+	for len(seen) < 2 {
+		select {
+		case ev := <-events:
+			seen[ev.Kind] = true
+		case <-timeout:
+			t.Fatalf("timed out waiting for started/ready events, saw: %v", seen)
+		}
+	}
+	if !seen["started"] || !seen["ready"] {
+		t.Fatalf("expected both started and ready events, saw: %v", seen)
+	}
+}
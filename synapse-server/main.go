@@ -1,17 +1,21 @@
 package main
 
 import (
+	"context"
+	"github.com/google/uuid"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
-	"github.com/google/uuid"
-
 
-	"github.com/ThisaraWeerakoon/Synpase-Go-Connector-PoC/protocol" 
+	"github.com/ThisaraWeerakoon/Synpase-Go-Connector-PoC/protocol"
 )
 
+// shutdownTimeout bounds how long the server waits for in-flight connector
+// operations to drain before escalating shutdown to SIGKILL.
+const shutdownTimeout = 15 * time.Second
+
 func main() {
 	log.Println("Synapse Go Server: Initializing...")
 
@@ -20,7 +24,6 @@ func main() {
 	definitionsDir := "../connector-definitions"
 	connectorsBaseDir := "../connectors"
 
-
 	cm, err := NewConnectorManager(definitionsDir, connectorsBaseDir)
 	if err != nil {
 		log.Fatalf("Synapse Go Server: Failed to initialize ConnectorManager: %v", err)
@@ -82,7 +85,9 @@ func main() {
 	signal.Notify(quitChannel, syscall.SIGINT, syscall.SIGTERM)
 	<-quitChannel
 
-	log.Println("Synapse Go Server: Received shutdown signal. Cleaning up...")
-	cm.ShutdownAll()
+	log.Println("Synapse Go Server: Received shutdown signal. Draining in-flight operations...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	cm.ShutdownAll(shutdownCtx)
 	log.Println("Synapse Go Server: Shutdown complete.")
-}
\ No newline at end of file
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// connectorBinaryPath is where the tests in this package (and the real
+// server, via ConnectorDefinition.ExecutablePathRelativeToConnectorsDir)
+// expect to find the built SimpleFileConnector executable.
+const connectorBinaryPath = "../connectors/simple-file-connector/simple-file-connector"
+
+// TestMain builds the SimpleFileConnector binary once before any test or
+// benchmark in this package runs, so they exercise a real connector process
+// without every developer/CI run having to remember a separate build step.
+func TestMain(m *testing.M) {
+	if _, err := os.Stat(connectorBinaryPath); os.IsNotExist(err) {
+		cmd := exec.Command("go", "build", "-o", connectorBinaryPath, "./simple-file-connector")
+		cmd.Dir = "../connectors"
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to build SimpleFileConnector for tests: %v\n", err)
+			os.Exit(1)
+		}
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to stat SimpleFileConnector binary: %v\n", err)
+		os.Exit(1)
+	}
+
+	os.Exit(m.Run())
+}
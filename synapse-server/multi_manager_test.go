@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ThisaraWeerakoon/Synpase-Go-Connector-PoC/protocol"
+)
+
+// newManager is a small helper shared by the regression tests in this file;
+// each gets its own definitions dir and data dir, the same way
+// newBenchManager does for the benchmarks.
+func newManager(t *testing.T) *ConnectorManager {
+	t.Helper()
+
+	defsDir := t.TempDir()
+	def := fmt.Sprintf(`{
+		"name": "SimpleFileConnector",
+		"executable_path_relative_to_connectors_dir": "simple-file-connector/simple-file-connector",
+		"default_config": {"baseDirectory": %q},
+		"max_concurrent": 8
+	}`, t.TempDir())
+	if err := os.WriteFile(filepath.Join(defsDir, "simple-file-connector.json"), []byte(def), 0644); err != nil {
+		t.Fatalf("failed to write connector definition: %v", err)
+	}
+
+	cm, err := NewConnectorManager(defsDir, "../connectors")
+	if err != nil {
+		t.Fatalf("failed to create ConnectorManager: %v", err)
+	}
+	return cm
+}
+
+// TestSequentialManagersShutdownCleanly guards against a prior bug where
+// every ConnectorManager installed its own SIGCHLD handler and raced every
+// other live manager's Wait4(-1, ...) for the next exiting child. A manager
+// that lost the race never saw its own child's exit, so instance.exited was
+// never closed and shutdownInstance hung forever waiting on it. Creating
+// several managers back to back and shutting each down in turn reproduces
+// that race if it regresses.
+func TestSequentialManagersShutdownCleanly(t *testing.T) {
+	for i := 0; i < 4; i++ {
+		cm := newManager(t)
+
+		var wg sync.WaitGroup
+		for j := 0; j < 10; j++ {
+			wg.Add(1)
+			go func(j int) {
+				defer wg.Done()
+				cm.Invoke("SimpleFileConnector", "create", nil, map[string]interface{}{
+					"filename": fmt.Sprintf("f-%d-%d.txt", i, j),
+					"content":  "x",
+				}, protocol.MessageContext{MessageID: fmt.Sprintf("m-%d-%d", i, j)})
+			}(j)
+		}
+		wg.Wait()
+
+		done := make(chan struct{})
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			cm.ShutdownAll(ctx)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(15 * time.Second):
+			t.Fatalf("iteration %d: ShutdownAll hung - a child's exit was likely reaped by a different manager's handler", i)
+		}
+	}
+}